@@ -0,0 +1,141 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// contextKey is the type this package's own context keys are declared
+// with, so they can never collide with a key defined by another package.
+type contextKey string
+
+const (
+	requestIDContextKey contextKey = "request_id"
+	traceIDContextKey   contextKey = "trace_id"
+	spanIDContextKey    contextKey = "span_id"
+)
+
+// contextField pairs a field name (as it appears on emitted entries) with
+// the context key its value is read from.
+type contextField struct {
+	name string
+	key  interface{}
+}
+
+// contextFieldsMutex guards contextFields, which RegisterContextKey can
+// append to from any goroutine (e.g. an init-time registration racing a
+// request already being logged).
+var contextFieldsMutex sync.RWMutex
+
+// contextFields lists every field WithContext pulls out of a
+// context.Context, in order. It is pre-populated with request/trace/span
+// IDs; RegisterContextKey appends further fields, so callers can surface
+// their own request-scoped values (a tenant ID, a tracing library's own
+// span key, ...) the same way.
+var contextFields = []contextField{
+	{name: "request_id", key: requestIDContextKey},
+	{name: "trace_id", key: traceIDContextKey},
+	{name: "span_id", key: spanIDContextKey},
+}
+
+// RegisterContextKey adds a context key to extract as the named field on
+// every Entry built via WithContext. It's typically called once at
+// startup.
+func RegisterContextKey(fieldName string, key interface{}) {
+	contextFieldsMutex.Lock()
+	defer contextFieldsMutex.Unlock()
+	contextFields = append(contextFields, contextField{name: fieldName, key: key})
+}
+
+// NewRequestContext stamps a freshly generated request ID onto ctx and
+// returns the derived context along with the ID, so callers can thread the
+// same value through logging, response headers, and downstream calls.
+func NewRequestContext(ctx context.Context) (context.Context, string) {
+	requestID := newCorrelationID()
+	return context.WithValue(ctx, requestIDContextKey, requestID), requestID
+}
+
+// newCorrelationID returns a short random hex identifier, falling back to
+// a timestamp if the system random source is unavailable.
+func newCorrelationID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// WithContext starts a structured Entry on the default logger, populated
+// with every registered context field found in ctx.
+func WithContext(ctx context.Context) *Entry {
+	return defaultLogger.WithContext(ctx)
+}
+
+// WithContext starts a structured Entry on this logger, populated with
+// every registered context field found in ctx.
+func (this *PackageLogger) WithContext(ctx context.Context) *Entry {
+	contextFieldsMutex.RLock()
+	fields := make([]contextField, len(contextFields))
+	copy(fields, contextFields)
+	contextFieldsMutex.RUnlock()
+
+	entry := newEntry(this)
+	for _, field := range fields {
+		if value := ctx.Value(field.key); value != nil {
+			entry = entry.WithField(field.name, value)
+		}
+	}
+	return entry
+}
+
+// DebugContext emits a DEBUG entry carrying ctx's registered fields (e.g.
+// request/trace/span IDs), so a single operation's logs can be grepped or
+// joined across goroutines.
+func DebugContext(ctx context.Context, message string, args ...interface{}) string {
+	return WithContext(ctx).Debug(message, args...)
+}
+
+// InfoContext emits an INFO entry carrying ctx's registered fields.
+func InfoContext(ctx context.Context, message string, args ...interface{}) string {
+	return WithContext(ctx).Info(message, args...)
+}
+
+// NoticeContext emits a NOTICE entry carrying ctx's registered fields.
+func NoticeContext(ctx context.Context, message string, args ...interface{}) string {
+	return WithContext(ctx).Notice(message, args...)
+}
+
+// WarningContext emits a WARNING entry carrying ctx's registered fields.
+func WarningContext(ctx context.Context, message string, args ...interface{}) error {
+	return WithContext(ctx).Warning(message, args...)
+}
+
+// ErrorContext emits an ERROR entry carrying ctx's registered fields.
+func ErrorContext(ctx context.Context, message string, args ...interface{}) error {
+	return WithContext(ctx).Error(message, args...)
+}
+
+// CriticalContext emits a CRITICAL entry carrying ctx's registered fields.
+func CriticalContext(ctx context.Context, message string, args ...interface{}) error {
+	return WithContext(ctx).Critical(message, args...)
+}
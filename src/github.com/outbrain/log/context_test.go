@@ -0,0 +1,66 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestWithContextExtractsRequestID(t *testing.T) {
+	ctx, requestID := NewRequestContext(context.Background())
+
+	entry := WithContext(ctx)
+	if entry.Fields["request_id"] != requestID {
+		t.Fatalf("expected request_id field %q, got %v", requestID, entry.Fields["request_id"])
+	}
+}
+
+func TestRegisterContextKeyAddsField(t *testing.T) {
+	type testKey string
+	const key = testKey("tenant_id")
+
+	RegisterContextKey("tenant_id", key)
+
+	ctx := context.WithValue(context.Background(), key, "tenant-42")
+	entry := WithContext(ctx)
+	if entry.Fields["tenant_id"] != "tenant-42" {
+		t.Fatalf("expected tenant_id field, got %v", entry.Fields["tenant_id"])
+	}
+}
+
+// TestWithContextConcurrentWithRegisterContextKey exercises
+// RegisterContextKey racing WithContext from other goroutines. Run with
+// -race.
+func TestWithContextConcurrentWithRegisterContextKey(t *testing.T) {
+	type raceKey string
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				RegisterContextKey("race_field", raceKey("race"))
+			} else {
+				WithContext(context.Background())
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,134 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Entry is a single in-flight log record carrying a set of structured
+// fields. Entries are created via WithField/WithFields (either the
+// package-level functions or a PackageLogger's own) and are emitted by
+// calling one of the severity methods, mirroring the top-level
+// Debug/Info/... functions.
+type Entry struct {
+	logger    *PackageLogger
+	Level     LogLevel
+	Message   string
+	Timestamp time.Time
+	Fields    map[string]interface{}
+}
+
+func newEntry(logger *PackageLogger) *Entry {
+	return &Entry{
+		logger: logger,
+		Fields: make(map[string]interface{}),
+	}
+}
+
+// fieldOrder returns the entry's field names sorted alphabetically, so that
+// formatters emit a stable, diffable order.
+func (this *Entry) fieldOrder() []string {
+	keys := make([]string, 0, len(this.Fields))
+	for key := range this.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WithField returns a new Entry on the default logger carrying key/value.
+func WithField(key string, value interface{}) *Entry {
+	return defaultLogger.WithField(key, value)
+}
+
+// WithFields returns a new Entry on the default logger carrying the given
+// fields.
+func WithFields(fields map[string]interface{}) *Entry {
+	return defaultLogger.WithFields(fields)
+}
+
+// WithField returns a copy of this Entry with key/value added.
+func (this *Entry) WithField(key string, value interface{}) *Entry {
+	return this.withFields(map[string]interface{}{key: value})
+}
+
+// WithFields returns a copy of this Entry with the given fields added.
+func (this *Entry) WithFields(fields map[string]interface{}) *Entry {
+	return this.withFields(fields)
+}
+
+func (this *Entry) withFields(fields map[string]interface{}) *Entry {
+	merged := make(map[string]interface{}, len(this.Fields)+len(fields))
+	for key, value := range this.Fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return &Entry{logger: this.logger, Fields: merged}
+}
+
+func (this *Entry) Debug(message string, args ...interface{}) string {
+	return this.logger.emitEntry(this, DEBUG, message, args...)
+}
+
+func (this *Entry) Debugf(message string, args ...interface{}) string {
+	return this.logger.emitFormattedEntry(this, DEBUG, message, args...)
+}
+
+func (this *Entry) Info(message string, args ...interface{}) string {
+	return this.logger.emitEntry(this, INFO, message, args...)
+}
+
+func (this *Entry) Infof(message string, args ...interface{}) string {
+	return this.logger.emitFormattedEntry(this, INFO, message, args...)
+}
+
+func (this *Entry) Notice(message string, args ...interface{}) string {
+	return this.logger.emitEntry(this, NOTICE, message, args...)
+}
+
+func (this *Entry) Noticef(message string, args ...interface{}) string {
+	return this.logger.emitFormattedEntry(this, NOTICE, message, args...)
+}
+
+func (this *Entry) Warning(message string, args ...interface{}) error {
+	return errors.New(this.logger.emitEntry(this, WARNING, message, args...))
+}
+
+func (this *Entry) Warningf(message string, args ...interface{}) error {
+	return errors.New(this.logger.emitFormattedEntry(this, WARNING, message, args...))
+}
+
+func (this *Entry) Error(message string, args ...interface{}) error {
+	return errors.New(this.logger.emitEntry(this, ERROR, message, args...))
+}
+
+func (this *Entry) Errorf(message string, args ...interface{}) error {
+	return errors.New(this.logger.emitFormattedEntry(this, ERROR, message, args...))
+}
+
+func (this *Entry) Critical(message string, args ...interface{}) error {
+	return errors.New(this.logger.emitEntry(this, CRITICAL, message, args...))
+}
+
+func (this *Entry) Criticalf(message string, args ...interface{}) error {
+	return errors.New(this.logger.emitFormattedEntry(this, CRITICAL, message, args...))
+}
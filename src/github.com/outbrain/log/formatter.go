@@ -0,0 +1,132 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Formatter turns a log Entry into the string that gets written out. Callers
+// select one via SetFormatter; TextFormatter is used until they do.
+type Formatter interface {
+	Format(entry *Entry) string
+}
+
+// defaultFormatter is the Formatter applied to every emitted entry.
+var defaultFormatter Formatter = &TextFormatter{}
+
+// SetFormatter replaces the Formatter used for all subsequent log entries.
+func SetFormatter(formatter Formatter) {
+	defaultFormatter = formatter
+}
+
+// TextFormatter renders an entry the way this package always has:
+// "<timestamp> <level> <message>", with any structured fields appended as
+// "key=value" pairs in alphabetical order.
+type TextFormatter struct{}
+
+func (this *TextFormatter) Format(entry *Entry) string {
+	entryString := fmt.Sprintf("%s %s %s", entry.Timestamp.Format(timeFormat), entry.Level, entry.Message)
+	for _, key := range entry.fieldOrder() {
+		entryString += fmt.Sprintf(" %s=%v", key, entry.Fields[key])
+	}
+	return entryString
+}
+
+// JSONFormatter renders an entry as a single line of JSON, with the
+// timestamp, level and message alongside any structured fields.
+type JSONFormatter struct{}
+
+func (this *JSONFormatter) Format(entry *Entry) string {
+	record := make(map[string]interface{}, len(entry.Fields)+3)
+	for key, value := range entry.Fields {
+		record[key] = value
+	}
+	record["timestamp"] = entry.Timestamp.Format(timeFormat)
+	record["level"] = entry.Level.String()
+	record["message"] = entry.Message
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Sprintf("%s %s %s (log: failed to marshal fields: %+v)", entry.Timestamp.Format(timeFormat), entry.Level, entry.Message, err)
+	}
+	return string(data)
+}
+
+// levelColors maps each LogLevel to the ANSI color code StructuredFormatter
+// uses to highlight it.
+var levelColors = map[LogLevel]string{
+	FATAL:    "\x1b[35m",
+	CRITICAL: "\x1b[31m",
+	ERROR:    "\x1b[31m",
+	WARNING:  "\x1b[33m",
+	NOTICE:   "\x1b[36m",
+	INFO:     "\x1b[32m",
+	DEBUG:    "\x1b[37m",
+}
+
+const colorReset = "\x1b[0m"
+
+// StructuredFormatter renders an entry as a colorized level plus "key=value"
+// fields when writing to a TTY, falling back to TextFormatter's plain output
+// otherwise (e.g. when stderr is redirected to a file or pipe).
+type StructuredFormatter struct {
+	// ForceColors forces colorized output even when the output is not a TTY.
+	ForceColors bool
+}
+
+func (this *StructuredFormatter) Format(entry *Entry) string {
+	if !this.colorsEnabled() {
+		return (&TextFormatter{}).Format(entry)
+	}
+	color := levelColors[entry.Level]
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s%-8s%s %s", entry.Timestamp.Format(timeFormat), color, entry.Level, colorReset, entry.Message)
+	for _, key := range entry.fieldOrder() {
+		fmt.Fprintf(&buf, " %s%s=%v%s", color, key, entry.Fields[key], colorReset)
+	}
+	return buf.String()
+}
+
+func (this *StructuredFormatter) colorsEnabled() bool {
+	if this.ForceColors {
+		return true
+	}
+	// A Formatter only ever sees the Entry, not the Sink it's about to be
+	// written to, so TTY auto-detection can only be trusted while stderr
+	// is actually where entries are going. Once SetOutput/AddSink has
+	// redirected or supplemented logging (e.g. to a file or syslog), fall
+	// back to plain text rather than risk writing raw ANSI escapes into a
+	// non-terminal destination.
+	if !soleOutputIsStderr() {
+		return false
+	}
+	return isTerminal(os.Stderr)
+}
+
+// isTerminal reports whether f is attached to a character device (a TTY)
+// rather than a regular file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (info.Mode() & os.ModeCharDevice) != 0
+}
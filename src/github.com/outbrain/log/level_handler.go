@@ -0,0 +1,132 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// levelByName maps a case-insensitive level name to its LogLevel.
+var levelByName = map[string]LogLevel{
+	"fatal":    FATAL,
+	"critical": CRITICAL,
+	"error":    ERROR,
+	"warning":  WARNING,
+	"notice":   NOTICE,
+	"info":     INFO,
+	"debug":    DEBUG,
+}
+
+// ParseLevel parses a level name case-insensitively, e.g. "debug" or "INFO".
+func ParseLevel(name string) (LogLevel, error) {
+	level, found := levelByName[strings.ToLower(name)]
+	if !found {
+		return 0, fmt.Errorf("log: unrecognized level %q", name)
+	}
+	return level, nil
+}
+
+// levelsDocument is the JSON shape LevelHandler reads and writes: the
+// global level plus every named package logger's effective level, keyed the
+// same way NewPackageLogger keys it ("repo/pkg").
+type levelsDocument struct {
+	Global   string            `json:"global"`
+	Packages map[string]string `json:"packages,omitempty"`
+}
+
+func currentLevels() levelsDocument {
+	doc := levelsDocument{Global: GetLevel().String()}
+
+	packageLoggersMutex.RLock()
+	defer packageLoggersMutex.RUnlock()
+	if len(packageLoggers) > 0 {
+		doc.Packages = make(map[string]string, len(packageLoggers))
+		for key, logger := range packageLoggers {
+			doc.Packages[key] = logger.GetLevel().String()
+		}
+	}
+	return doc
+}
+
+// LevelHandler returns an http.Handler exposing the current log levels as
+// JSON on GET, and accepting a PUT/POST of the same shape to change them
+// at runtime, without a restart. A request may set "global" and/or any
+// key under "packages" (as returned by a prior GET); keys it omits are
+// left unchanged.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeLevelsJSON(w, currentLevels())
+		case http.MethodPut, http.MethodPost:
+			handleSetLevels(w, r)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func handleSetLevels(w http.ResponseWriter, r *http.Request) {
+	var req levelsDocument
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Parse every level up front, so a single bad name in the request
+	// rejects it wholesale rather than applying a partial set of changes.
+	var globalLevel *LogLevel
+	if req.Global != "" {
+		level, err := ParseLevel(req.Global)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		globalLevel = &level
+	}
+	packageLevels := make(map[string]LogLevel, len(req.Packages))
+	for key, name := range req.Packages {
+		level, err := ParseLevel(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		packageLevels[key] = level
+	}
+
+	if globalLevel != nil {
+		SetLevel(*globalLevel)
+	}
+	packageLoggersMutex.RLock()
+	for key, level := range packageLevels {
+		if logger, found := packageLoggers[key]; found {
+			logger.SetLevel(level)
+		}
+	}
+	packageLoggersMutex.RUnlock()
+
+	writeLevelsJSON(w, currentLevels())
+}
+
+func writeLevelsJSON(w http.ResponseWriter, doc levelsDocument) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doc)
+}
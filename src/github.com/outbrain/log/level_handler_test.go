@@ -0,0 +1,96 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLevelHandlerGetReturnsGlobalLevel(t *testing.T) {
+	defer SetLevel(GetLevel())
+	SetLevel(INFO)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/log-level", nil)
+	LevelHandler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	var doc levelsDocument
+	if err := json.NewDecoder(recorder.Body).Decode(&doc); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if doc.Global != "INFO" {
+		t.Fatalf("expected global level INFO, got %q", doc.Global)
+	}
+}
+
+func TestLevelHandlerPutChangesGlobalLevel(t *testing.T) {
+	defer SetLevel(GetLevel())
+	SetLevel(INFO)
+
+	body, _ := json.Marshal(levelsDocument{Global: "debug"})
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPut, "/log-level", bytes.NewReader(body))
+	LevelHandler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if GetLevel() != DEBUG {
+		t.Fatalf("expected global level to change to DEBUG, got %s", GetLevel())
+	}
+}
+
+func TestLevelHandlerPutRejectsUnknownLevelWithoutSideEffects(t *testing.T) {
+	defer SetLevel(GetLevel())
+	SetLevel(INFO)
+
+	body, _ := json.Marshal(levelsDocument{Global: "bogus"})
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPut, "/log-level", bytes.NewReader(body))
+	LevelHandler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", recorder.Code)
+	}
+	if GetLevel() != INFO {
+		t.Fatalf("expected global level to be left unchanged on a bad request, got %s", GetLevel())
+	}
+}
+
+func TestLevelHandlerPutSetsPackageLevel(t *testing.T) {
+	logger := NewPackageLogger("test-repo", "handler-pkg")
+	key := "test-repo/handler-pkg"
+
+	body, _ := json.Marshal(levelsDocument{Packages: map[string]string{key: "error"}})
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPut, "/log-level", bytes.NewReader(body))
+	LevelHandler().ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if logger.GetLevel() != ERROR {
+		t.Fatalf("expected package logger level ERROR, got %s", logger.GetLevel())
+	}
+}
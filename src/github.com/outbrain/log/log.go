@@ -17,10 +17,10 @@
 package log
 
 import (
-	"time"
 	"fmt"
 	"os"
 	"errors"
+	"sync"
 )
 
 // LogLevel indicates the severity of a log entry
@@ -51,38 +51,37 @@ const (
 
 const timeFormat = "2006-01-02 15:04:05"
 
-// globalLogLevel indicates the global level filter for all logs (only entries with level equals or higher 
+// globalLogLevelMutex guards globalLogLevel, which is read on every log
+// call and written from both SetLevel and the SIGUSR1/SIGUSR2 handlers.
+var globalLogLevelMutex sync.RWMutex
+
+// globalLogLevel indicates the global level filter for all logs (only entries with level equals or higher
 // than this value will be logged)
 var globalLogLevel LogLevel = DEBUG
 
 // SetLevel sets the global log level. Only entries with level equals or higher than
 // this value will be logged
 func SetLevel(logLevel LogLevel) {
+	globalLogLevelMutex.Lock()
+	defer globalLogLevelMutex.Unlock()
 	globalLogLevel = logLevel
 }
 
 // GetLevel returns current global log level
 func GetLevel() LogLevel {
+	globalLogLevelMutex.RLock()
+	defer globalLogLevelMutex.RUnlock()
 	return globalLogLevel
 }
 
-// logFormattedEntry nicely formats and emits a log entry
+// logFormattedEntry nicely formats and emits a log entry via the default logger
 func logFormattedEntry(logLevel LogLevel, message string, args ...interface{}) string {
-	if logLevel > globalLogLevel {
-		return ""
-	} 
-	entryString := fmt.Sprintf("%s %s %s", time.Now().Format(timeFormat), logLevel, fmt.Sprintf(message, args...))
-	fmt.Fprintln(os.Stderr, entryString)
-	return entryString
+	return defaultLogger.emitFormattedEntry(nil, logLevel, message, args...)
 }
 
-// logEntry emits a formatted log entry 
+// logEntry emits a log entry via the default logger
 func logEntry(logLevel LogLevel, message string, args ...interface{}) string {
-	entryString := message
-	for _, s := range args {
-		entryString += fmt.Sprintf(" %s", s)
-	}
-	return logFormattedEntry(logLevel, entryString)
+	return defaultLogger.emitEntry(nil, logLevel, message, args...)
 }
 
 // logErrorEntry emits a log entry based on given error object
@@ -155,6 +154,7 @@ func Criticale(err error) error {
 // Fatal emits a FATAL level entry and exists the program
 func Fatal(message string, args ...interface{}) error {
 	logEntry(FATAL, message, args...)
+	flushSinks()
 	os.Exit(1)
 	return errors.New(logEntry(CRITICAL, message, args...))
 }
@@ -162,6 +162,7 @@ func Fatal(message string, args ...interface{}) error {
 // Fatalf emits a FATAL level entry and exists the program
 func Fatalf(message string, args ...interface{}) error {
 	logEntry(FATAL, message, args...)
+	flushSinks()
 	os.Exit(1)
 	return errors.New(logFormattedEntry(CRITICAL, message, args...))
 }
@@ -169,6 +170,7 @@ func Fatalf(message string, args ...interface{}) error {
 // Fatale emits a FATAL level entry and exists the program
 func Fatale(err error) error {
 	logErrorEntry(FATAL, err)
+	flushSinks()
 	os.Exit(1)
 	return err
 }
@@ -0,0 +1,148 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PackageLogger is a logger scoped to a single repo/package pair, in the
+// spirit of capnslog's per-repository loggers: it can be filtered
+// independently of the global level, so a noisy subsystem can be turned up
+// (or down) without affecting everyone else's output.
+type PackageLogger struct {
+	repo string
+	pkg  string
+
+	levelMutex sync.RWMutex
+	level      *LogLevel // nil means "inherit globalLogLevel"
+}
+
+// defaultLogger backs the package-level Debug/Info/... functions, so that
+// code written against those functions keeps working unchanged.
+var defaultLogger = &PackageLogger{}
+
+// packageLoggersMutex guards packageLoggers, which is both written by
+// NewPackageLogger and read/iterated by LevelHandler.
+var packageLoggersMutex sync.RWMutex
+
+// packageLoggers holds every logger created via NewPackageLogger, keyed by
+// "repo/pkg", so that callers can look levels up or change them centrally.
+var packageLoggers = make(map[string]*PackageLogger)
+
+// NewPackageLogger returns the logger for the given repo/package pair,
+// creating it on first use. Repeated calls with the same arguments return
+// the same instance.
+func NewPackageLogger(repo, pkg string) *PackageLogger {
+	key := repo + "/" + pkg
+
+	packageLoggersMutex.RLock()
+	logger, found := packageLoggers[key]
+	packageLoggersMutex.RUnlock()
+	if found {
+		return logger
+	}
+
+	packageLoggersMutex.Lock()
+	defer packageLoggersMutex.Unlock()
+	if logger, found := packageLoggers[key]; found {
+		return logger
+	}
+	logger = &PackageLogger{repo: repo, pkg: pkg}
+	packageLoggers[key] = logger
+	return logger
+}
+
+// SetLevel sets the level filter for this logger only, overriding the
+// global level for entries emitted through it.
+func (this *PackageLogger) SetLevel(logLevel LogLevel) {
+	this.levelMutex.Lock()
+	defer this.levelMutex.Unlock()
+	this.level = &logLevel
+}
+
+// GetLevel returns this logger's effective level: its own override if one
+// was set via SetLevel, otherwise the global level.
+func (this *PackageLogger) GetLevel() LogLevel {
+	this.levelMutex.RLock()
+	level := this.level
+	this.levelMutex.RUnlock()
+	if level != nil {
+		return *level
+	}
+	return GetLevel()
+}
+
+// prefix returns the "repo/pkg: " label prepended to this logger's
+// messages, or the empty string for the unnamed default logger.
+func (this *PackageLogger) prefix() string {
+	if this.repo == "" && this.pkg == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s: ", this.repo, this.pkg)
+}
+
+// WithField starts a structured Entry scoped to this logger.
+func (this *PackageLogger) WithField(key string, value interface{}) *Entry {
+	return newEntry(this).WithField(key, value)
+}
+
+// WithFields starts a structured Entry scoped to this logger.
+func (this *PackageLogger) WithFields(fields map[string]interface{}) *Entry {
+	return newEntry(this).WithFields(fields)
+}
+
+// emitEntry concatenates message with args the way the original
+// space-separated log functions (Debug, Info, ...) always have, then emits
+// it carrying source's fields (or no fields, if source is nil).
+func (this *PackageLogger) emitEntry(source *Entry, logLevel LogLevel, message string, args ...interface{}) string {
+	entryString := message
+	for _, arg := range args {
+		entryString += fmt.Sprintf(" %s", arg)
+	}
+	return this.emitFormattedEntry(source, logLevel, entryString)
+}
+
+// emitFormattedEntry treats message as a fmt format string, the way the
+// original Debugf/Infof/... functions always have, then emits it carrying
+// source's fields (or no fields, if source is nil).
+//
+// source is never written to: callers may hold onto an *Entry returned by
+// WithField/WithFields and log through it repeatedly, from multiple
+// goroutines and at multiple severities, so each call here builds its own
+// transient Entry for the Level/Timestamp/Message of just this emission.
+func (this *PackageLogger) emitFormattedEntry(source *Entry, logLevel LogLevel, message string, args ...interface{}) string {
+	if logLevel > this.GetLevel() {
+		return ""
+	}
+	if !sampledIn(logLevel) {
+		return ""
+	}
+	emitted := newEntry(this)
+	if source != nil {
+		emitted.Fields = source.Fields
+	}
+	emitted.Level = logLevel
+	emitted.Timestamp = time.Now()
+	emitted.Message = this.prefix() + fmt.Sprintf(message, args...)
+
+	entryString := defaultFormatter.Format(emitted)
+	writeToSinks(logLevel, entryString)
+	return entryString
+}
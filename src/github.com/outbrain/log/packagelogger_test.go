@@ -0,0 +1,87 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNewPackageLoggerReturnsSameInstance(t *testing.T) {
+	a := NewPackageLogger("test-repo", "pkg-a")
+	b := NewPackageLogger("test-repo", "pkg-a")
+	if a != b {
+		t.Fatalf("expected NewPackageLogger to return the same instance for the same repo/pkg")
+	}
+}
+
+func TestPackageLoggerLevelInheritsGlobalUntilSet(t *testing.T) {
+	defer SetLevel(GetLevel())
+	SetLevel(WARNING)
+
+	logger := NewPackageLogger("test-repo", "pkg-b")
+	if logger.GetLevel() != WARNING {
+		t.Fatalf("expected logger to inherit global level WARNING, got %s", logger.GetLevel())
+	}
+
+	logger.SetLevel(DEBUG)
+	if logger.GetLevel() != DEBUG {
+		t.Fatalf("expected logger's own level DEBUG to override global, got %s", logger.GetLevel())
+	}
+
+	SetLevel(ERROR)
+	if logger.GetLevel() != DEBUG {
+		t.Fatalf("expected logger's own level to still override a later global change, got %s", logger.GetLevel())
+	}
+}
+
+func TestNewPackageLoggerConcurrentCreation(t *testing.T) {
+	var wg sync.WaitGroup
+	loggers := make([]*PackageLogger, 50)
+	for i := range loggers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			loggers[i] = NewPackageLogger("test-repo", "pkg-concurrent")
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < len(loggers); i++ {
+		if loggers[i] != loggers[0] {
+			t.Fatalf("expected every concurrent NewPackageLogger call to return the same instance")
+		}
+	}
+}
+
+// TestEntryReuseAcrossGoroutines exercises the pattern emitFormattedEntry's
+// doc comment allows: build an *Entry once, then log through it repeatedly
+// from multiple goroutines and at multiple severities. Run with -race.
+func TestEntryReuseAcrossGoroutines(t *testing.T) {
+	entry := WithField("request_id", "abc")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry.Info("hi")
+			entry.Error("bad")
+		}()
+	}
+	wg.Wait()
+}
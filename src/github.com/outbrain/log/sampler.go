@@ -0,0 +1,240 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Sampler decides whether an entry at its configured level should be
+// emitted right now, protecting against a failing loop (e.g. a replica
+// repeatedly logging the same error) flooding stderr or a downstream log
+// pipeline.
+type Sampler interface {
+	Allow() bool
+}
+
+// suppressedFlushInterval is how often suppressed-entry counts are rolled
+// up into a single summary line.
+const suppressedFlushInterval = 10 * time.Second
+
+var samplersMutex sync.Mutex
+var samplers = make(map[LogLevel]Sampler)
+var suppressedCounts = make(map[LogLevel]int)
+
+// SetSampler installs sampler as the gate for every entry at level level.
+// A level with no sampler configured is never sampled.
+func SetSampler(level LogLevel, sampler Sampler) {
+	samplersMutex.Lock()
+	defer samplersMutex.Unlock()
+	samplers[level] = sampler
+}
+
+// sampledIn reports whether an entry at level should be emitted, bumping
+// the suppressed counter for level when it should not.
+func sampledIn(level LogLevel) bool {
+	samplersMutex.Lock()
+	sampler, found := samplers[level]
+	samplersMutex.Unlock()
+	if !found {
+		return true
+	}
+	if sampler.Allow() {
+		return true
+	}
+	samplersMutex.Lock()
+	suppressedCounts[level]++
+	samplersMutex.Unlock()
+	return false
+}
+
+func init() {
+	go func() {
+		for range time.Tick(suppressedFlushInterval) {
+			flushSuppressedCounts()
+		}
+	}()
+}
+
+// flushSuppressedCounts emits, at its own level, a single "N messages
+// suppressed" summary for each level that dropped at least one entry
+// since the last flush. It bypasses the level's own sampler -- otherwise
+// a saturated sampler would suppress its own suppression summary.
+func flushSuppressedCounts() {
+	samplersMutex.Lock()
+	counts := make(map[LogLevel]int, len(suppressedCounts))
+	for level, count := range suppressedCounts {
+		if count > 0 {
+			counts[level] = count
+			suppressedCounts[level] = 0
+		}
+	}
+	samplersMutex.Unlock()
+
+	for level, count := range counts {
+		if level > defaultLogger.GetLevel() {
+			continue
+		}
+		entry := newEntry(defaultLogger)
+		entry.Level = level
+		entry.Timestamp = time.Now()
+		entry.Message = fmt.Sprintf("%d messages suppressed", count)
+		writeToSinks(level, defaultFormatter.Format(entry))
+	}
+}
+
+// TokenBucketSampler allows up to burst entries immediately, then refills
+// at refillPerSec tokens per second, dropping entries while the bucket is
+// empty.
+type TokenBucketSampler struct {
+	mutex        sync.Mutex
+	tokens       float64
+	burst        float64
+	refillPerSec float64
+	lastRefill   time.Time
+}
+
+// NewTokenBucketSampler returns a Sampler with the given burst capacity
+// and steady-state refill rate.
+func NewTokenBucketSampler(burst int, refillPerSec float64) *TokenBucketSampler {
+	return &TokenBucketSampler{
+		tokens:       float64(burst),
+		burst:        float64(burst),
+		refillPerSec: refillPerSec,
+		lastRefill:   time.Now(),
+	}
+}
+
+func (this *TokenBucketSampler) Allow() bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	now := time.Now()
+	this.tokens += now.Sub(this.lastRefill).Seconds() * this.refillPerSec
+	if this.tokens > this.burst {
+		this.tokens = this.burst
+	}
+	this.lastRefill = now
+
+	if this.tokens < 1 {
+		return false
+	}
+	this.tokens--
+	return true
+}
+
+// FirstNThenEveryMSampler allows the first n entries, then only every
+// m-th entry after that -- useful for a call site that would otherwise
+// log once per loop iteration, where the first few occurrences matter
+// most and the rest are just noise.
+type FirstNThenEveryMSampler struct {
+	mutex sync.Mutex
+	n     int
+	m     int
+	seen  int
+}
+
+// NewFirstNThenEveryMSampler returns a Sampler that allows the first n
+// entries, then every m-th one after that.
+func NewFirstNThenEveryMSampler(n, m int) *FirstNThenEveryMSampler {
+	return &FirstNThenEveryMSampler{n: n, m: m}
+}
+
+func (this *FirstNThenEveryMSampler) Allow() bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.seen++
+	if this.seen <= this.n {
+		return true
+	}
+	return this.m > 0 && (this.seen-this.n)%this.m == 0
+}
+
+// everyThrottle gates a call site, identified by its format string, to at
+// most one emitted entry per interval.
+type everyThrottle struct {
+	interval time.Duration
+}
+
+var everyMutex sync.Mutex
+var everyLastEmit = make(map[string]time.Time)
+
+// Every returns a throttle that allows at most one entry per interval,
+// deduplicated per call site on the message string passed to the returned
+// throttle's severity method (args are not part of the key), e.g.:
+//
+//	log.Every(5 * time.Second).Warning("still waiting for", host)
+func Every(interval time.Duration) *everyThrottle {
+	return &everyThrottle{interval: interval}
+}
+
+func (this *everyThrottle) allow(key string) bool {
+	everyMutex.Lock()
+	defer everyMutex.Unlock()
+
+	now := time.Now()
+	if last, found := everyLastEmit[key]; found && now.Sub(last) < this.interval {
+		return false
+	}
+	everyLastEmit[key] = now
+	return true
+}
+
+func (this *everyThrottle) Debug(message string, args ...interface{}) string {
+	if !this.allow(message) {
+		return ""
+	}
+	return logEntry(DEBUG, message, args...)
+}
+
+func (this *everyThrottle) Info(message string, args ...interface{}) string {
+	if !this.allow(message) {
+		return ""
+	}
+	return logEntry(INFO, message, args...)
+}
+
+func (this *everyThrottle) Notice(message string, args ...interface{}) string {
+	if !this.allow(message) {
+		return ""
+	}
+	return logEntry(NOTICE, message, args...)
+}
+
+func (this *everyThrottle) Warning(message string, args ...interface{}) error {
+	if !this.allow(message) {
+		return nil
+	}
+	return Warning(message, args...)
+}
+
+func (this *everyThrottle) Error(message string, args ...interface{}) error {
+	if !this.allow(message) {
+		return nil
+	}
+	return Error(message, args...)
+}
+
+func (this *everyThrottle) Critical(message string, args ...interface{}) error {
+	if !this.allow(message) {
+		return nil
+	}
+	return Critical(message, args...)
+}
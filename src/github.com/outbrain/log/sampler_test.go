@@ -0,0 +1,43 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import "testing"
+
+func TestTokenBucketSamplerAllowsBurstThenBlocks(t *testing.T) {
+	sampler := NewTokenBucketSampler(3, 0)
+
+	for i := 0; i < 3; i++ {
+		if !sampler.Allow() {
+			t.Fatalf("expected burst entry %d to be allowed", i)
+		}
+	}
+	if sampler.Allow() {
+		t.Fatalf("expected sampler to block once the burst is exhausted")
+	}
+}
+
+func TestFirstNThenEveryMSampler(t *testing.T) {
+	sampler := NewFirstNThenEveryMSampler(2, 3)
+
+	want := []bool{true, true, false, false, true, false, false, true}
+	for i, w := range want {
+		if got := sampler.Allow(); got != w {
+			t.Fatalf("entry %d: expected Allow()=%v, got %v", i, w, got)
+		}
+	}
+}
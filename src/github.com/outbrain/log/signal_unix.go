@@ -0,0 +1,52 @@
+//go:build !windows
+// +build !windows
+
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// init installs the standard signal-driven runtime controls: SIGHUP
+// closes and reopens every registered sink (the logrotate-integration
+// hook), while SIGUSR1/SIGUSR2 cycle the global verbosity up/down without
+// a restart.
+func init() {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for range sighup {
+			Reopen()
+		}
+	}()
+
+	verbosity := make(chan os.Signal, 1)
+	signal.Notify(verbosity, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for sig := range verbosity {
+			if sig == syscall.SIGUSR1 {
+				IncreaseVerbosity()
+			} else {
+				DecreaseVerbosity()
+			}
+		}
+	}()
+}
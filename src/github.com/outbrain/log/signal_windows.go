@@ -0,0 +1,40 @@
+//go:build windows
+// +build windows
+
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// init installs the logrotate-integration handler: on SIGHUP, every
+// registered sink is closed and reopened. Windows has no SIGUSR1/SIGUSR2
+// equivalent, so verbosity cycling is only wired to signals on !windows;
+// IncreaseVerbosity/DecreaseVerbosity remain callable directly.
+func init() {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for range sighup {
+			Reopen()
+		}
+	}()
+}
@@ -0,0 +1,165 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Sink is a log output destination. Each sink filters independently via its
+// own minimum level (set via AddSink), on top of whatever level a
+// PackageLogger itself applies.
+type Sink interface {
+	io.Writer
+	// Reopen closes and reopens the underlying destination -- the standard
+	// logrotate-integration hook: rotate the file out from under the
+	// process, then signal it to reopen the (now fresh) path.
+	Reopen() error
+	// Close releases any resources held by the sink.
+	Close() error
+}
+
+// registeredSink pairs a Sink with the minimum level it accepts.
+type registeredSink struct {
+	sink     Sink
+	minLevel LogLevel
+}
+
+var sinksMutex sync.Mutex
+
+// sinks lists every destination an emitted entry is written to. It starts
+// out with a single stderr sink, matching this package's original, sole
+// behavior.
+var sinks = []*registeredSink{
+	{sink: &streamSink{w: os.Stderr}, minLevel: DEBUG},
+}
+
+// streamSink adapts a plain io.Writer, which has no rotation semantics of
+// its own, into a Sink.
+type streamSink struct {
+	w io.Writer
+}
+
+func (this *streamSink) Write(p []byte) (int, error) { return this.w.Write(p) }
+func (this *streamSink) Reopen() error                { return nil }
+func (this *streamSink) Close() error                 { return nil }
+
+// SetOutput redirects the default sink to w, keeping its configured
+// minimum level. This is the simplest way to send all logging somewhere
+// other than stderr, e.g. a file the caller already opened.
+func SetOutput(w io.Writer) {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	sinks[0] = &registeredSink{sink: &streamSink{w: w}, minLevel: sinks[0].minLevel}
+}
+
+// AddSink registers an additional destination that receives every entry at
+// or above minLevel, alongside whatever sinks are already configured.
+func AddSink(sink Sink, minLevel LogLevel) {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	sinks = append(sinks, &registeredSink{sink: sink, minLevel: minLevel})
+}
+
+// levelWriter is implemented by sinks (e.g. SyslogSink) that need to know
+// an entry's level at write time, typically to map it onto some
+// destination-specific notion of severity. writeToSinks prefers this over
+// plain Write when a sink supports it.
+type levelWriter interface {
+	WriteLevel(level LogLevel, p []byte) (int, error)
+}
+
+// writeToSinks writes entryString, already formatted and without a
+// trailing newline, to every registered sink whose minLevel accepts
+// logLevel. A sink write failure is reported to stderr rather than
+// silently dropped, since the whole point of a sink is to be where an
+// operator expects entries to show up.
+func writeToSinks(logLevel LogLevel, entryString string) {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	data := []byte(entryString + "\n")
+	for _, registered := range sinks {
+		if logLevel > registered.minLevel {
+			continue
+		}
+		var err error
+		if lw, ok := registered.sink.(levelWriter); ok {
+			_, err = lw.WriteLevel(logLevel, data)
+		} else {
+			_, err = registered.sink.Write(data)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "log: sink write failed: %v (entry: %s)\n", err, entryString)
+		}
+	}
+}
+
+// soleOutputIsStderr reports whether logging is still going only to the
+// original, unmodified stderr sink -- i.e. neither SetOutput nor AddSink
+// has redirected or supplemented it. StructuredFormatter uses this to
+// decide whether checking the real os.Stderr for a TTY still reflects
+// where entries are actually being written.
+func soleOutputIsStderr() bool {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	if len(sinks) != 1 {
+		return false
+	}
+	stream, ok := sinks[0].sink.(*streamSink)
+	if !ok {
+		return false
+	}
+	return stream.w == io.Writer(os.Stderr)
+}
+
+// Reopen closes and reopens every registered sink. Callers normally invoke
+// this from a SIGHUP handler (installed automatically, see signal_unix.go
+// and signal_windows.go)
+// after an external logrotate has moved a sink's file aside.
+func Reopen() error {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	var firstErr error
+	for _, registered := range sinks {
+		if err := registered.sink.Reopen(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// flusher is implemented by sinks that buffer writes and need an explicit
+// flush before the process exits.
+type flusher interface {
+	Flush() error
+}
+
+// flushSinks flushes every sink that supports it. It is called from the
+// Fatal/Fatalf/Fatale exit paths so buffered entries aren't lost to an
+// abrupt os.Exit.
+func flushSinks() {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	for _, registered := range sinks {
+		if f, ok := registered.sink.(flusher); ok {
+			f.Flush()
+		}
+	}
+}
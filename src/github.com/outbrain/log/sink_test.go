@@ -0,0 +1,172 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSinkRotatesAtMaxSizeBytes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	sink, err := NewFileSink(path, 10, 0)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	if _, err := sink.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if _, err := sink.Write([]byte("trigger rotation")); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("failed to read temp dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the original file plus one rotated file, got %d entries: %v", len(entries), entries)
+	}
+}
+
+func TestFileSinkRotatesAtMaxAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	sink, err := NewFileSink(path, 0, time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	time.Sleep(5 * time.Millisecond)
+	if _, err := sink.Write([]byte("stale")); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("failed to read temp dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the original file plus one rotated file, got %d entries: %v", len(entries), entries)
+	}
+}
+
+func TestFileSinkReopenStartsFreshFileAfterRename(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	sink, err := NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	defer sink.Close()
+
+	if _, err := sink.Write([]byte("before rotation\n")); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	// Simulate an external logrotate: move the file out from under the
+	// sink, then call Reopen, the SIGHUP handler's response.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatalf("failed to rename file out from under the sink: %v", err)
+	}
+	if err := sink.Reopen(); err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+
+	if _, err := sink.Write([]byte("after reopen\n")); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+
+	freshContents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read reopened path: %v", err)
+	}
+	if string(freshContents) != "after reopen\n" {
+		t.Fatalf("expected the reopened file to only contain post-reopen writes, got %q", freshContents)
+	}
+
+	rotatedContents, err := os.ReadFile(path + ".1")
+	if err != nil {
+		t.Fatalf("failed to read renamed-aside file: %v", err)
+	}
+	if string(rotatedContents) != "before rotation\n" {
+		t.Fatalf("expected the renamed-aside file to keep the pre-rotation contents, got %q", rotatedContents)
+	}
+}
+
+// testSink is a minimal in-memory Sink for exercising AddSink/SetOutput
+// level routing without touching the filesystem.
+type testSink struct {
+	buf bytes.Buffer
+}
+
+func (this *testSink) Write(p []byte) (int, error) { return this.buf.Write(p) }
+func (this *testSink) Reopen() error                { return nil }
+func (this *testSink) Close() error                 { return nil }
+
+func TestAddSinkRoutesByMinLevel(t *testing.T) {
+	defer resetSinksForTest()
+
+	warnAndAbove := &testSink{}
+	everything := &testSink{}
+	AddSink(warnAndAbove, WARNING)
+	AddSink(everything, DEBUG)
+
+	writeToSinks(DEBUG, "a debug entry")
+	writeToSinks(ERROR, "an error entry")
+
+	if bytes.Contains(warnAndAbove.buf.Bytes(), []byte("a debug entry")) {
+		t.Fatalf("expected the WARNING-and-above sink to not receive a DEBUG entry, got %q", warnAndAbove.buf.String())
+	}
+	if !bytes.Contains(warnAndAbove.buf.Bytes(), []byte("an error entry")) {
+		t.Fatalf("expected the WARNING-and-above sink to receive an ERROR entry, got %q", warnAndAbove.buf.String())
+	}
+	if !bytes.Contains(everything.buf.Bytes(), []byte("a debug entry")) {
+		t.Fatalf("expected the DEBUG-and-above sink to receive a DEBUG entry, got %q", everything.buf.String())
+	}
+	if !bytes.Contains(everything.buf.Bytes(), []byte("an error entry")) {
+		t.Fatalf("expected the DEBUG-and-above sink to receive an ERROR entry, got %q", everything.buf.String())
+	}
+}
+
+func TestSetOutputReplacesDefaultSink(t *testing.T) {
+	defer resetSinksForTest()
+
+	replacement := &testSink{}
+	SetOutput(replacement)
+
+	writeToSinks(INFO, "routed to replacement")
+
+	if !bytes.Contains(replacement.buf.Bytes(), []byte("routed to replacement")) {
+		t.Fatalf("expected SetOutput's sink to receive the entry, got %q", replacement.buf.String())
+	}
+}
+
+// resetSinksForTest restores the package-level sink list to its
+// just-stderr default, so tests that call AddSink/SetOutput don't leak
+// sinks into later tests.
+func resetSinksForTest() {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+	sinks = []*registeredSink{
+		{sink: &streamSink{w: os.Stderr}, minLevel: DEBUG},
+	}
+}
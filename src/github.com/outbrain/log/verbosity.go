@@ -0,0 +1,36 @@
+/*
+   Copyright 2014 Outbrain Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package log
+
+// IncreaseVerbosity moves the global log level one step towards DEBUG, if
+// it isn't there already. It backs the SIGUSR1 handler, but is exported so
+// callers can wire it up to some other trigger (e.g. an admin command).
+func IncreaseVerbosity() {
+	if level := GetLevel(); level < DEBUG {
+		SetLevel(level + 1)
+		Noticef("log: verbosity increased to %s", GetLevel())
+	}
+}
+
+// DecreaseVerbosity moves the global log level one step towards FATAL, if
+// it isn't there already. It backs the SIGUSR2 handler.
+func DecreaseVerbosity() {
+	if level := GetLevel(); level > FATAL {
+		SetLevel(level - 1)
+		Noticef("log: verbosity decreased to %s", GetLevel())
+	}
+}